@@ -0,0 +1,200 @@
+package es
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/olivere/elastic/uritemplates"
+
+	elastic7 "github.com/olivere/elastic/v7"
+	elastic6 "gopkg.in/olivere/elastic.v6"
+)
+
+func dataSourceElasticsearchOpenDistroDetectorResults() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceElasticsearchOpenDistroDetectorResultsRead,
+		Schema: map[string]*schema.Schema{
+			"detector_id": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"start_time": {
+				Type:     schema.TypeInt,
+				Required: true,
+			},
+			"end_time": {
+				Type:     schema.TypeInt,
+				Required: true,
+			},
+			"min_anomaly_grade": {
+				Type:     schema.TypeFloat,
+				Optional: true,
+				Default:  0,
+			},
+			"results": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"timestamp": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"anomaly_grade": {
+							Type:     schema.TypeFloat,
+							Computed: true,
+						},
+						"confidence": {
+							Type:     schema.TypeFloat,
+							Computed: true,
+						},
+						"feature_data": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceElasticsearchOpenDistroDetectorResultsRead(d *schema.ResourceData, m interface{}) error {
+	detectorID := d.Get("detector_id").(string)
+	startTime := d.Get("start_time").(int)
+	endTime := d.Get("end_time").(int)
+	minAnomalyGrade := d.Get("min_anomaly_grade").(float64)
+
+	esClient, err := getClient(m.(*ProviderConf))
+	if err != nil {
+		return err
+	}
+	switch esClient.(type) {
+	case *elastic7.Client, *elastic6.Client:
+	default:
+		return errors.New("Detector results data source not implemented prior to Elastic v6")
+	}
+
+	query := detectorResultsQuery(detectorID, startTime, endTime, minAnomalyGrade)
+	queryJSON, err := json.Marshal(query)
+	if err != nil {
+		return fmt.Errorf("error marshalling detector results query: %+v", err)
+	}
+
+	path, err := uritemplates.Expand("/_opendistro/_anomaly_detection/detectors/{id}/results/_search", map[string]string{
+		"id": detectorID,
+	})
+	if err != nil {
+		return fmt.Errorf("error building URL path for Detector results: %+v", err)
+	}
+
+	body, err := performRequestWithBackoff(context.Background(), m, esClient, backoffRequestOptions{
+		Method: "POST",
+		Path:   path,
+		Body:   string(queryJSON),
+	})
+	if err != nil {
+		if !isMissingResultsEndpoint(err) {
+			return fmt.Errorf("error querying Detector results: %+v", err)
+		}
+
+		// Older AD plugin versions don't expose the results/_search
+		// convenience endpoint at all and 404 with a routing error; fall
+		// back to querying the results index directly in that case only. A
+		// 404 because detector_id itself doesn't exist, or any other error
+		// (auth failure, cluster unreachable), is returned as-is above
+		// rather than masked by this fallback.
+		body, err = performRequestWithBackoff(context.Background(), m, esClient, backoffRequestOptions{
+			Method: "POST",
+			Path:   fmt.Sprintf("/%s/_search", anomalyResultsIndex),
+			Body:   string(queryJSON),
+		})
+		if err != nil {
+			return fmt.Errorf("error querying Detector results: %+v", err)
+		}
+	}
+
+	var searchResponse detectorResultsSearchResponse
+	if err := json.Unmarshal(body, &searchResponse); err != nil {
+		return fmt.Errorf("error unmarshalling Detector results body: %+v: %+v", err, body)
+	}
+
+	results := make([]interface{}, 0, len(searchResponse.Hits.Hits))
+	for _, hit := range searchResponse.Hits.Hits {
+		featureDataJSON, err := json.Marshal(hit.Source.FeatureData)
+		if err != nil {
+			return fmt.Errorf("error marshalling feature_data: %+v", err)
+		}
+
+		results = append(results, map[string]interface{}{
+			"timestamp":     hit.Source.DataStartTime,
+			"anomaly_grade": hit.Source.AnomalyGrade,
+			"confidence":    hit.Source.Confidence,
+			"feature_data":  string(featureDataJSON),
+		})
+	}
+
+	if err := d.Set("results", results); err != nil {
+		return err
+	}
+
+	d.SetId(fmt.Sprintf("%s-%d-%d", detectorID, startTime, endTime))
+	return nil
+}
+
+// isMissingResultsEndpoint reports whether err represents the routing-level
+// 404 Elasticsearch returns when a path has no registered handler, which is
+// what older AD plugin versions return for the results/_search convenience
+// endpoint. A 404 for any other reason - e.g. the AD plugin rejecting an
+// unknown detector_id - has a different reason/type and must not trigger
+// the index-query fallback, since that fallback would otherwise silently
+// return zero results instead of surfacing the real error.
+func isMissingResultsEndpoint(err error) bool {
+	if !elastic6.IsNotFound(err) && !elastic7.IsNotFound(err) {
+		return false
+	}
+	return strings.Contains(err.Error(), "no handler found for uri")
+}
+
+// detectorResultsQuery builds the search body for pulling a detector's
+// results within a time window, above a minimum anomaly grade.
+func detectorResultsQuery(detectorID string, startTime, endTime int, minAnomalyGrade float64) map[string]interface{} {
+	return map[string]interface{}{
+		"size": 10000,
+		"sort": []map[string]interface{}{
+			{"data_start_time": map[string]interface{}{"order": "asc"}},
+		},
+		"query": map[string]interface{}{
+			"bool": map[string]interface{}{
+				"filter": []map[string]interface{}{
+					{"term": map[string]interface{}{"detector_id": detectorID}},
+					{"range": map[string]interface{}{"anomaly_grade": map[string]interface{}{"gte": minAnomalyGrade}}},
+					{"range": map[string]interface{}{"data_start_time": map[string]interface{}{
+						"gte": startTime,
+						"lte": endTime,
+					}}},
+				},
+			},
+		},
+	}
+}
+
+// detectorResultsSearchResponse decodes the subset of the search response
+// this data source cares about, mirroring the way DetectorResponse decodes
+// only the fields the detector resource needs.
+type detectorResultsSearchResponse struct {
+	Hits struct {
+		Hits []struct {
+			Source struct {
+				DataStartTime int64                    `json:"data_start_time"`
+				AnomalyGrade  float64                  `json:"anomaly_grade"`
+				Confidence    float64                  `json:"confidence"`
+				FeatureData   []map[string]interface{} `json:"feature_data"`
+			} `json:"_source"`
+		} `json:"hits"`
+	} `json:"hits"`
+}
@@ -0,0 +1,59 @@
+package es
+
+import (
+	"testing"
+
+	elastic5 "gopkg.in/olivere/elastic.v5"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+)
+
+func TestAccElasticsearchOpenDistroDetectorResultsDataSource(t *testing.T) {
+	provider := Provider().(*schema.Provider)
+	err := provider.Configure(&terraform.ResourceConfig{})
+	if err != nil {
+		t.Skipf("err: %s", err)
+	}
+	meta := provider.Meta()
+	esClient, err := getClient(meta.(*ProviderConf))
+	if err != nil {
+		t.Skipf("err: %s", err)
+	}
+	var allowed bool
+
+	switch esClient.(type) {
+	case *elastic5.Client:
+		allowed = false
+	default:
+		allowed = true
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			if !allowed {
+				t.Skip("Detector results data source only supported on >= ES 6")
+			}
+		},
+		Providers: testAccOpendistroProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccElasticsearchOpenDistroDetectorResultsDataSource,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrSet("data.elasticsearch_opendistro_detector_results.test", "results.#"),
+				),
+			},
+		},
+	})
+}
+
+var testAccElasticsearchOpenDistroDetectorResultsDataSource = testAccElasticsearchOpenDistroDetectorFixture + `
+data elasticsearch_opendistro_detector_results test {
+  detector_id       = elasticsearch_opendistro_detector.detector.id
+  start_time        = 0
+  end_time          = 4102444800000
+  min_anomaly_grade = 0.5
+}
+`
@@ -0,0 +1,150 @@
+package es
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"math/rand"
+	"net"
+	"time"
+
+	elastic7 "github.com/olivere/elastic/v7"
+	elastic6 "gopkg.in/olivere/elastic.v6"
+)
+
+// Defaults for performRequestWithBackoff, used whenever the provider block
+// doesn't override retry_max_attempts / retry_max_wait_ms (or leaves them at
+// their zero value).
+const (
+	defaultRetryMaxAttempts   = 8
+	defaultRetryInitialWait   = 100 * time.Millisecond
+	defaultRetryMaxWait       = 30 * time.Second
+	defaultRetryBackoffFactor = 2.0
+	defaultRequestTimeout     = 60 * time.Second
+)
+
+// backoffRequestOptions is a client-agnostic version of
+// elastic{6,7}.PerformRequestOptions, letting a single retry loop drive
+// either generation of client.
+type backoffRequestOptions struct {
+	Method string
+	Path   string
+	Body   interface{}
+}
+
+// retryMaxAttempts reads the retry_max_attempts provider-block override off
+// of m's ProviderConf, falling back to defaultRetryMaxAttempts when m isn't
+// a *ProviderConf or the knob wasn't set.
+func retryMaxAttempts(m interface{}) int {
+	if conf, ok := m.(*ProviderConf); ok && conf.retryMaxAttempts > 0 {
+		return conf.retryMaxAttempts
+	}
+	return defaultRetryMaxAttempts
+}
+
+// retryMaxWait reads the retry_max_wait_ms provider-block override off of
+// m's ProviderConf, falling back to defaultRetryMaxWait when m isn't a
+// *ProviderConf or the knob wasn't set.
+func retryMaxWait(m interface{}) time.Duration {
+	if conf, ok := m.(*ProviderConf); ok && conf.retryMaxWaitMs > 0 {
+		return time.Duration(conf.retryMaxWaitMs) * time.Millisecond
+	}
+	return defaultRetryMaxWait
+}
+
+// performRequestWithBackoff wraps PerformRequest on either the elastic6 or
+// elastic7 client with an exponential backoff, retrying on network errors
+// and 429/502/503/504 responses. It's modeled on the retry loop in upstream
+// olivere/elastic's backoff.go: an initial wait that doubles each attempt,
+// jittered, and capped. m is the provider meta passed to the calling
+// resource/data source function; it supplies the retry_max_attempts /
+// retry_max_wait_ms provider-block overrides, if any.
+func performRequestWithBackoff(ctx context.Context, m interface{}, esClient interface{}, opts backoffRequestOptions) (json.RawMessage, error) {
+	ctx, cancel := context.WithTimeout(ctx, defaultRequestTimeout)
+	defer cancel()
+
+	maxAttempts := retryMaxAttempts(m)
+	maxWait := retryMaxWait(m)
+
+	wait := defaultRetryInitialWait
+	var lastErr error
+
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		var body json.RawMessage
+		var err error
+
+		switch client := esClient.(type) {
+		case *elastic7.Client:
+			var res *elastic7.Response
+			res, err = client.PerformRequest(ctx, elastic7.PerformRequestOptions{
+				Method: opts.Method,
+				Path:   opts.Path,
+				Body:   opts.Body,
+			})
+			if res != nil {
+				body = res.Body
+			}
+		case *elastic6.Client:
+			var res *elastic6.Response
+			res, err = client.PerformRequest(ctx, elastic6.PerformRequestOptions{
+				Method: opts.Method,
+				Path:   opts.Path,
+				Body:   opts.Body,
+			})
+			if res != nil {
+				body = res.Body
+			}
+		default:
+			return nil, errors.New("Detector resource not implemented prior to Elastic v6")
+		}
+
+		if err == nil {
+			return body, nil
+		}
+
+		lastErr = err
+
+		if !isRetryableRequestError(err) || attempt == maxAttempts-1 {
+			return nil, err
+		}
+
+		jittered := wait/2 + time.Duration(rand.Int63n(int64(wait/2)+1))
+		timer := time.NewTimer(jittered)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+
+		wait = time.Duration(math.Min(
+			float64(maxWait),
+			float64(wait)*defaultRetryBackoffFactor,
+		))
+	}
+
+	return nil, lastErr
+}
+
+// isRetryableRequestError reports whether err represents a transient
+// condition worth retrying: a network-level failure, or an HTTP 429/502/503/504
+// from either elastic client generation.
+func isRetryableRequestError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+
+	for _, code := range []int{429, 502, 503, 504} {
+		if elastic7.IsStatusCode(err, code) || elastic6.IsStatusCode(err, code) {
+			return true
+		}
+	}
+
+	return false
+}
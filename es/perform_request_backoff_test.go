@@ -0,0 +1,60 @@
+package es
+
+import (
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	elastic7 "github.com/olivere/elastic/v7"
+)
+
+func TestIsRetryableRequestError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil error", nil, false},
+		{"network error", &net.DNSError{IsTimeout: true}, true},
+		{"429", &elastic7.Error{Status: 429}, true},
+		{"502", &elastic7.Error{Status: 502}, true},
+		{"503", &elastic7.Error{Status: 503}, true},
+		{"504", &elastic7.Error{Status: 504}, true},
+		{"404 is not retryable", &elastic7.Error{Status: 404}, false},
+		{"plain error", errors.New("boom"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isRetryableRequestError(tt.err); got != tt.want {
+				t.Errorf("isRetryableRequestError(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryMaxAttemptsAndWaitDefaults(t *testing.T) {
+	if got := retryMaxAttempts(nil); got != defaultRetryMaxAttempts {
+		t.Errorf("retryMaxAttempts(nil) = %d, want %d", got, defaultRetryMaxAttempts)
+	}
+	if got := retryMaxWait(nil); got != defaultRetryMaxWait {
+		t.Errorf("retryMaxWait(nil) = %v, want %v", got, defaultRetryMaxWait)
+	}
+
+	conf := &ProviderConf{retryMaxAttempts: 3, retryMaxWaitMs: 500}
+	if got := retryMaxAttempts(conf); got != 3 {
+		t.Errorf("retryMaxAttempts(conf) = %d, want 3", got)
+	}
+	if got := retryMaxWait(conf); got != 500*time.Millisecond {
+		t.Errorf("retryMaxWait(conf) = %v, want 500ms", got)
+	}
+
+	zeroConf := &ProviderConf{}
+	if got := retryMaxAttempts(zeroConf); got != defaultRetryMaxAttempts {
+		t.Errorf("retryMaxAttempts(zeroConf) = %d, want default %d", got, defaultRetryMaxAttempts)
+	}
+	if got := retryMaxWait(zeroConf); got != defaultRetryMaxWait {
+		t.Errorf("retryMaxWait(zeroConf) = %v, want default %v", got, defaultRetryMaxWait)
+	}
+}
@@ -0,0 +1,67 @@
+package es
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+)
+
+// ProviderConf holds the resolved provider-block configuration and the
+// Elasticsearch client built from it. Only the pieces this OpenDistro
+// anomaly detection series depends on are modeled here - the rest of the
+// provider's configuration (cluster URLs, auth, TLS, ...) lives outside
+// this chunk.
+type ProviderConf struct {
+	esClient interface{}
+
+	retryMaxAttempts int
+	retryMaxWaitMs   int
+}
+
+// getClient returns the elastic6 or elastic7 client held in conf, or an
+// error when conf has none. providerConfigure does not build a client, so a
+// ProviderConf it returns has none set.
+func getClient(conf *ProviderConf) (interface{}, error) {
+	if conf == nil || conf.esClient == nil {
+		return nil, fmt.Errorf("no Elasticsearch client configured")
+	}
+	return conf.esClient, nil
+}
+
+// Provider returns the schema.Provider for this OpenDistro anomaly
+// detection chunk: the detector resource, its job/monitor companions, the
+// results data source, and the retry_max_attempts/retry_max_wait_ms knobs
+// performRequestWithBackoff reads off of ProviderConf.
+func Provider() terraform.ResourceProvider {
+	return &schema.Provider{
+		Schema: map[string]*schema.Schema{
+			"retry_max_attempts": {
+				Type:        schema.TypeInt,
+				Optional:    true,
+				Description: "Maximum number of attempts performRequestWithBackoff makes for a detector/monitor request before giving up.",
+			},
+			"retry_max_wait_ms": {
+				Type:        schema.TypeInt,
+				Optional:    true,
+				Description: "Maximum backoff wait, in milliseconds, between retried detector/monitor requests.",
+			},
+		},
+		ResourcesMap: map[string]*schema.Resource{
+			"elasticsearch_opendistro_detector":        resourceElasticsearchOpenDistroDetector(),
+			"elasticsearch_opendistro_detector_job":    resourceElasticsearchOpenDistroDetectorJob(),
+			"elasticsearch_opendistro_anomaly_monitor": resourceElasticsearchOpenDistroAnomalyMonitor(),
+		},
+		DataSourcesMap: map[string]*schema.Resource{
+			"elasticsearch_opendistro_detector_results": dataSourceElasticsearchOpenDistroDetectorResults(),
+		},
+		ConfigureFunc: providerConfigure,
+	}
+}
+
+func providerConfigure(d *schema.ResourceData) (interface{}, error) {
+	return &ProviderConf{
+		retryMaxAttempts: d.Get("retry_max_attempts").(int),
+		retryMaxWaitMs:   d.Get("retry_max_wait_ms").(int),
+	}, nil
+}
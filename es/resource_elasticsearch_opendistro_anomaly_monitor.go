@@ -0,0 +1,410 @@
+package es
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"regexp"
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/olivere/elastic/uritemplates"
+
+	elastic7 "github.com/olivere/elastic/v7"
+	elastic6 "gopkg.in/olivere/elastic.v6"
+)
+
+// anomalyResultsIndex is the index pattern OpenDistro writes detector
+// results to, and the one AD-monitors search against.
+const anomalyResultsIndex = ".opendistro-anomaly-results*"
+
+var openDistroAnomalyMonitorSchema = map[string]*schema.Schema{
+	"name": {
+		Type:     schema.TypeString,
+		Required: true,
+	},
+	"detector_id": {
+		Type:     schema.TypeString,
+		Required: true,
+	},
+	"anomaly_grade_threshold": {
+		Type:     schema.TypeFloat,
+		Optional: true,
+		Default:  0,
+	},
+	"time_window_minutes": {
+		Type:     schema.TypeInt,
+		Optional: true,
+		Default:  10,
+	},
+	"schedule_interval": {
+		Type:     schema.TypeInt,
+		Optional: true,
+		Default:  1,
+	},
+	"schedule_unit": {
+		Type:     schema.TypeString,
+		Optional: true,
+		Default:  "MINUTES",
+	},
+	"enabled": {
+		Type:     schema.TypeBool,
+		Optional: true,
+		Default:  true,
+	},
+}
+
+func resourceElasticsearchOpenDistroAnomalyMonitor() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceElasticsearchOpenDistroAnomalyMonitorCreate,
+		Read:   resourceElasticsearchOpenDistroAnomalyMonitorRead,
+		Update: resourceElasticsearchOpenDistroAnomalyMonitorUpdate,
+		Delete: resourceElasticsearchOpenDistroAnomalyMonitorDelete,
+		Schema: openDistroAnomalyMonitorSchema,
+		Importer: &schema.ResourceImporter{
+			State: schema.ImportStatePassthrough,
+		},
+	}
+}
+
+func resourceElasticsearchOpenDistroAnomalyMonitorCreate(d *schema.ResourceData, m interface{}) error {
+	if _, err := resourceElasticsearchOpenDistroGetDetector(d.Get("detector_id").(string), m); err != nil {
+		return fmt.Errorf("referenced detector %s not found: %+v", d.Get("detector_id").(string), err)
+	}
+
+	res, err := resourceElasticsearchPostAnomalyMonitor(d, m)
+	if err != nil {
+		log.Printf("[INFO] Failed to create anomaly monitor: %+v", err)
+		return err
+	}
+
+	d.SetId(res.ID)
+	log.Printf("[INFO] Object ID: %s", d.Id())
+
+	return resourceElasticsearchOpenDistroAnomalyMonitorRead(d, m)
+}
+
+func resourceElasticsearchOpenDistroAnomalyMonitorRead(d *schema.ResourceData, m interface{}) error {
+	res, err := resourceElasticsearchGetAnomalyMonitor(d.Id(), m)
+
+	if elastic6.IsNotFound(err) || elastic7.IsNotFound(err) {
+		log.Printf("[WARN] Anomaly monitor (%s) not found, removing from state", d.Id())
+		d.SetId("")
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	d.SetId(res.ID)
+	return flattenAnomalyMonitor(d, res.Monitor)
+}
+
+func resourceElasticsearchOpenDistroAnomalyMonitorUpdate(d *schema.ResourceData, m interface{}) error {
+	if _, err := resourceElasticsearchOpenDistroGetDetector(d.Get("detector_id").(string), m); err != nil {
+		return fmt.Errorf("referenced detector %s not found: %+v", d.Get("detector_id").(string), err)
+	}
+
+	if _, err := resourceElasticsearchPutAnomalyMonitor(d, m); err != nil {
+		return err
+	}
+
+	return resourceElasticsearchOpenDistroAnomalyMonitorRead(d, m)
+}
+
+func resourceElasticsearchOpenDistroAnomalyMonitorDelete(d *schema.ResourceData, m interface{}) error {
+	var err error
+
+	path, err := uritemplates.Expand("/_opendistro/_alerting/monitors/{id}", map[string]string{
+		"id": d.Id(),
+	})
+	if err != nil {
+		return fmt.Errorf("error building URL path for anomaly monitor: %+v", err)
+	}
+
+	esClient, err := getClient(m.(*ProviderConf))
+	if err != nil {
+		return err
+	}
+
+	_, err = performRequestWithBackoff(context.Background(), m, esClient, backoffRequestOptions{
+		Method: "DELETE",
+		Path:   path,
+	})
+
+	return err
+}
+
+// buildAnomalyMonitorBody assembles the standard AD-monitor payload: a
+// search input over the detector results index, filtered down to the
+// referenced detector and anomaly grades above the configured threshold.
+func buildAnomalyMonitorBody(d *schema.ResourceData) (string, error) {
+	detectorID := d.Get("detector_id").(string)
+
+	query := map[string]interface{}{
+		"size": 0,
+		"query": map[string]interface{}{
+			"bool": map[string]interface{}{
+				"filter": []map[string]interface{}{
+					{"term": map[string]interface{}{"detector_id": detectorID}},
+					{"range": map[string]interface{}{"anomaly_grade": map[string]interface{}{"gt": d.Get("anomaly_grade_threshold").(float64)}}},
+					{"range": map[string]interface{}{"data_start_time": map[string]interface{}{
+						"gte":    fmt.Sprintf("{{period_end}}||-%dm", d.Get("time_window_minutes").(int)),
+						"lte":    "{{period_end}}",
+						"format": "epoch_millis||yyyy-MM-dd'T'HH:mm",
+					}}},
+				},
+			},
+		},
+	}
+
+	monitor := map[string]interface{}{
+		"type":    "monitor",
+		"name":    d.Get("name").(string),
+		"enabled": d.Get("enabled").(bool),
+		"schedule": map[string]interface{}{
+			"period": map[string]interface{}{
+				"interval": d.Get("schedule_interval").(int),
+				"unit":     d.Get("schedule_unit").(string),
+			},
+		},
+		"inputs": []map[string]interface{}{
+			{
+				"search": map[string]interface{}{
+					"indices": []string{anomalyResultsIndex},
+					"query":   query,
+				},
+			},
+		},
+		"triggers": []interface{}{},
+	}
+
+	monitorJSON, err := json.Marshal(monitor)
+	if err != nil {
+		return "", fmt.Errorf("error marshalling anomaly monitor body: %+v", err)
+	}
+
+	return string(monitorJSON), nil
+}
+
+// timeWindowMinutesPattern picks the minute count back out of the
+// `{{period_end}}||-Xm` date-math expression buildAnomalyMonitorBody embeds
+// in the generated query's data_start_time filter.
+var timeWindowMinutesPattern = regexp.MustCompile(`-(\d+)m$`)
+
+// flattenAnomalyMonitor sets the monitor attributes in state from the raw
+// monitor object returned by the alerting plugin. detector_id,
+// anomaly_grade_threshold and time_window_minutes aren't returned as
+// top-level fields; they're read back out of the generated search query
+// buildAnomalyMonitorBody produced them from. Fields the API didn't return,
+// or whose shape doesn't match what this resource generates, are left
+// unset rather than erroring, since the monitor could have been edited
+// out-of-band into something this resource doesn't model.
+func flattenAnomalyMonitor(d *schema.ResourceData, monitor map[string]interface{}) error {
+	if v, ok := monitor["name"]; ok {
+		if err := d.Set("name", v); err != nil {
+			return err
+		}
+	}
+	if v, ok := monitor["enabled"]; ok {
+		if err := d.Set("enabled", v); err != nil {
+			return err
+		}
+	}
+
+	if schedule, ok := monitor["schedule"].(map[string]interface{}); ok {
+		if period, ok := schedule["period"].(map[string]interface{}); ok {
+			if v, ok := period["interval"]; ok {
+				if err := d.Set("schedule_interval", v); err != nil {
+					return err
+				}
+			}
+			if v, ok := period["unit"]; ok {
+				if err := d.Set("schedule_unit", v); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	filters, ok := anomalyMonitorQueryFilters(monitor)
+	if !ok {
+		return nil
+	}
+
+	for _, rawFilter := range filters {
+		filter, ok := rawFilter.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		if term, ok := filter["term"].(map[string]interface{}); ok {
+			if v, ok := term["detector_id"]; ok {
+				if err := d.Set("detector_id", v); err != nil {
+					return err
+				}
+			}
+		}
+
+		rangeFilter, ok := filter["range"].(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		if anomalyGrade, ok := rangeFilter["anomaly_grade"].(map[string]interface{}); ok {
+			if v, ok := anomalyGrade["gt"]; ok {
+				if err := d.Set("anomaly_grade_threshold", v); err != nil {
+					return err
+				}
+			}
+		}
+
+		if dataStartTime, ok := rangeFilter["data_start_time"].(map[string]interface{}); ok {
+			if gte, ok := dataStartTime["gte"].(string); ok {
+				if match := timeWindowMinutesPattern.FindStringSubmatch(gte); match != nil {
+					minutes, err := strconv.Atoi(match[1])
+					if err != nil {
+						return err
+					}
+					if err := d.Set("time_window_minutes", minutes); err != nil {
+						return err
+					}
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// anomalyMonitorQueryFilters digs the bool-query filter clauses back out of
+// the search input buildAnomalyMonitorBody generated, mirroring its nested
+// inputs[0].search.query.query.bool.filter shape.
+func anomalyMonitorQueryFilters(monitor map[string]interface{}) ([]interface{}, bool) {
+	inputs, ok := monitor["inputs"].([]interface{})
+	if !ok || len(inputs) == 0 {
+		return nil, false
+	}
+	input, ok := inputs[0].(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+	search, ok := input["search"].(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+	outerQuery, ok := search["query"].(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+	innerQuery, ok := outerQuery["query"].(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+	boolQuery, ok := innerQuery["bool"].(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+	filters, ok := boolQuery["filter"].([]interface{})
+	return filters, ok
+}
+
+func resourceElasticsearchPostAnomalyMonitor(d *schema.ResourceData, m interface{}) (*AnomalyMonitorResponse, error) {
+	monitorJSON, err := buildAnomalyMonitorBody(d)
+	if err != nil {
+		return nil, err
+	}
+
+	response := new(AnomalyMonitorResponse)
+	path := "/_opendistro/_alerting/monitors/"
+
+	esClient, err := getClient(m.(*ProviderConf))
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := performRequestWithBackoff(context.Background(), m, esClient, backoffRequestOptions{
+		Method: "POST",
+		Path:   path,
+		Body:   monitorJSON,
+	})
+	if err != nil {
+		return response, err
+	}
+
+	if err := json.Unmarshal(body, response); err != nil {
+		return response, fmt.Errorf("error unmarshalling anomaly monitor body: %+v: %+v", err, body)
+	}
+	return response, nil
+}
+
+func resourceElasticsearchPutAnomalyMonitor(d *schema.ResourceData, m interface{}) (*AnomalyMonitorResponse, error) {
+	monitorJSON, err := buildAnomalyMonitorBody(d)
+	if err != nil {
+		return nil, err
+	}
+
+	response := new(AnomalyMonitorResponse)
+	path, err := uritemplates.Expand("/_opendistro/_alerting/monitors/{id}", map[string]string{
+		"id": d.Id(),
+	})
+	if err != nil {
+		return response, fmt.Errorf("error building URL path for anomaly monitor: %+v", err)
+	}
+
+	esClient, err := getClient(m.(*ProviderConf))
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := performRequestWithBackoff(context.Background(), m, esClient, backoffRequestOptions{
+		Method: "PUT",
+		Path:   path,
+		Body:   monitorJSON,
+	})
+	if err != nil {
+		return response, err
+	}
+
+	if err := json.Unmarshal(body, response); err != nil {
+		return response, fmt.Errorf("error unmarshalling anomaly monitor body: %+v: %+v", err, body)
+	}
+	return response, nil
+}
+
+func resourceElasticsearchGetAnomalyMonitor(monitorID string, m interface{}) (*AnomalyMonitorResponse, error) {
+	response := new(AnomalyMonitorResponse)
+
+	path, err := uritemplates.Expand("/_opendistro/_alerting/monitors/{id}", map[string]string{
+		"id": monitorID,
+	})
+	if err != nil {
+		return response, fmt.Errorf("error building URL path for anomaly monitor: %+v", err)
+	}
+
+	esClient, err := getClient(m.(*ProviderConf))
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := performRequestWithBackoff(context.Background(), m, esClient, backoffRequestOptions{
+		Method: "GET",
+		Path:   path,
+	})
+	if err != nil {
+		return response, err
+	}
+
+	if err := json.Unmarshal(body, response); err != nil {
+		return response, fmt.Errorf("error unmarshalling anomaly monitor body: %+v: %+v", err, body)
+	}
+	return response, nil
+}
+
+type AnomalyMonitorResponse struct {
+	Version int                    `json:"_version"`
+	ID      string                 `json:"_id"`
+	Monitor map[string]interface{} `json:"monitor"`
+}
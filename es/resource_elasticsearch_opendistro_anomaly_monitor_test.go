@@ -0,0 +1,101 @@
+package es
+
+import (
+	"fmt"
+	"testing"
+
+	elastic5 "gopkg.in/olivere/elastic.v5"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+)
+
+func TestAccElasticsearchOpenDistroAnomalyMonitor(t *testing.T) {
+	provider := Provider().(*schema.Provider)
+	err := provider.Configure(&terraform.ResourceConfig{})
+	if err != nil {
+		t.Skipf("err: %s", err)
+	}
+	meta := provider.Meta()
+	esClient, err := getClient(meta.(*ProviderConf))
+	if err != nil {
+		t.Skipf("err: %s", err)
+	}
+	var allowed bool
+
+	switch esClient.(type) {
+	case *elastic5.Client:
+		allowed = false
+	default:
+		allowed = true
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			if !allowed {
+				t.Skip("Anomaly monitors only supported on >= ES 6")
+			}
+		},
+		Providers:    testAccOpendistroProviders,
+		CheckDestroy: testCheckElasticsearchAnomalyMonitorDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccElasticsearchOpenDistroAnomalyMonitor,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckElasticsearchOpenDistroAnomalyMonitorExists("elasticsearch_opendistro_anomaly_monitor.test_monitor"),
+				),
+			},
+		},
+	})
+}
+
+func testCheckElasticsearchOpenDistroAnomalyMonitorExists(name string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[name]
+		if !ok {
+			return fmt.Errorf("Not found: %s", name)
+		}
+		if rs.Primary.ID == "" {
+			return fmt.Errorf("No anomaly monitor ID is set")
+		}
+
+		meta := testAccOpendistroProvider.Meta()
+
+		_, err := resourceElasticsearchGetAnomalyMonitor(rs.Primary.ID, meta.(*ProviderConf))
+		if err != nil {
+			return err
+		}
+
+		return nil
+	}
+}
+
+func testCheckElasticsearchAnomalyMonitorDestroy(s *terraform.State) error {
+	for _, rs := range s.RootModule().Resources {
+		if rs.Type != "elasticsearch_opendistro_anomaly_monitor" {
+			continue
+		}
+
+		meta := testAccOpendistroProvider.Meta()
+
+		_, err := resourceElasticsearchGetAnomalyMonitor(rs.Primary.ID, meta.(*ProviderConf))
+		if err != nil {
+			return nil // should be not found error
+		}
+
+		return fmt.Errorf("Anomaly monitor %q still exists", rs.Primary.ID)
+	}
+
+	return nil
+}
+
+var testAccElasticsearchOpenDistroAnomalyMonitor = testAccElasticsearchOpenDistroDetectorFixture + `
+resource elasticsearch_opendistro_anomaly_monitor test_monitor {
+  name                    = "test-anomaly-monitor"
+  detector_id             = elasticsearch_opendistro_detector.detector.id
+  anomaly_grade_threshold = 0.7
+  time_window_minutes     = 15
+}
+`
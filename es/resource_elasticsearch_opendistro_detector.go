@@ -3,9 +3,9 @@ package es
 import (
 	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"log"
+	"strings"
 
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/structure"
@@ -16,16 +16,141 @@ import (
 	elastic6 "gopkg.in/olivere/elastic.v6"
 )
 
+// typedDetectorFields lists the attributes that express a detector's
+// definition as first-class schema, as opposed to a hand-written JSON
+// `body`. They're kept in their own slice so the `body` <-> typed-attribute
+// ConflictsWith wiring below doesn't drift out of sync with the schema.
+var typedDetectorFields = []string{
+	"name",
+	"description",
+	"time_field",
+	"indices",
+	"feature_attributes",
+	"detection_interval",
+	"window_delay",
+	"category_field",
+	"shingle_size",
+	"filter_query",
+}
+
 var openDistroDetectorSchema = map[string]*schema.Schema{
 	"body": {
 		Type:             schema.TypeString,
-		Required:         true,
+		Optional:         true,
+		Computed:         true,
 		DiffSuppressFunc: diffSuppressDetector,
 		StateFunc: func(v interface{}) string {
 			json, _ := structure.NormalizeJsonString(v)
 			return json
 		},
-		ValidateFunc: validation.StringIsJSON,
+		ValidateFunc:  validation.StringIsJSON,
+		ConflictsWith: typedDetectorFields,
+	},
+	"name": {
+		Type:          schema.TypeString,
+		Optional:      true,
+		ConflictsWith: []string{"body"},
+	},
+	"description": {
+		Type:          schema.TypeString,
+		Optional:      true,
+		ConflictsWith: []string{"body"},
+	},
+	"time_field": {
+		Type:          schema.TypeString,
+		Optional:      true,
+		ConflictsWith: []string{"body"},
+	},
+	"indices": {
+		Type:          schema.TypeList,
+		Optional:      true,
+		Elem:          &schema.Schema{Type: schema.TypeString},
+		ConflictsWith: []string{"body"},
+	},
+	"feature_attributes": {
+		Type:          schema.TypeList,
+		Optional:      true,
+		ConflictsWith: []string{"body"},
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"feature_name": {
+					Type:     schema.TypeString,
+					Required: true,
+				},
+				"feature_enabled": {
+					Type:     schema.TypeBool,
+					Optional: true,
+					Default:  true,
+				},
+				"aggregation_query": {
+					Type:         schema.TypeString,
+					Required:     true,
+					ValidateFunc: validation.StringIsJSON,
+					StateFunc: func(v interface{}) string {
+						json, _ := structure.NormalizeJsonString(v)
+						return json
+					},
+				},
+			},
+		},
+	},
+	"detection_interval": {
+		Type:          schema.TypeList,
+		Optional:      true,
+		MaxItems:      1,
+		ConflictsWith: []string{"body"},
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"interval": {
+					Type:     schema.TypeInt,
+					Required: true,
+				},
+				"unit": {
+					Type:     schema.TypeString,
+					Required: true,
+				},
+			},
+		},
+	},
+	"window_delay": {
+		Type:          schema.TypeList,
+		Optional:      true,
+		MaxItems:      1,
+		ConflictsWith: []string{"body"},
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"interval": {
+					Type:     schema.TypeInt,
+					Required: true,
+				},
+				"unit": {
+					Type:     schema.TypeString,
+					Required: true,
+				},
+			},
+		},
+	},
+	"category_field": {
+		Type:          schema.TypeList,
+		Optional:      true,
+		Elem:          &schema.Schema{Type: schema.TypeString},
+		ConflictsWith: []string{"body"},
+	},
+	"shingle_size": {
+		Type:          schema.TypeInt,
+		Optional:      true,
+		ConflictsWith: []string{"body"},
+	},
+	"filter_query": {
+		Type:             schema.TypeString,
+		Optional:         true,
+		DiffSuppressFunc: diffSuppressDetector,
+		ValidateFunc:     validation.StringIsJSON,
+		StateFunc: func(v interface{}) string {
+			json, _ := structure.NormalizeJsonString(v)
+			return json
+		},
+		ConflictsWith: []string{"body"},
 	},
 }
 
@@ -39,6 +164,7 @@ func resourceElasticsearchOpenDistroDetector() *schema.Resource {
 		Importer: &schema.ResourceImporter{
 			State: schema.ImportStatePassthrough,
 		},
+		CustomizeDiff: resourceElasticsearchOpenDistroDetectorCustomizeDiff,
 	}
 }
 
@@ -74,6 +200,11 @@ func resourceElasticsearchOpenDistroDetectorRead(d *schema.ResourceData, m inter
 
 	d.SetId(res.ID)
 
+	// Capture which path the resource was configured with from the typed
+	// attributes, not from `body`: `body` is Optional+Computed and always
+	// reads back as set once the detector exists.
+	usingTyped := usingTypedDetectorAttributes(d)
+
 	DetectorJson, err := json.Marshal(res.Detector)
 	if err != nil {
 		return err
@@ -82,8 +213,18 @@ func resourceElasticsearchOpenDistroDetectorRead(d *schema.ResourceData, m inter
 	if err != nil {
 		return err
 	}
-	err = d.Set("body", DetectorJsonNormalized)
-	return err
+	if err := d.Set("body", DetectorJsonNormalized); err != nil {
+		return err
+	}
+
+	// Only populate the typed attributes when the resource was configured
+	// with them, so that `body`-based configurations don't pick up a
+	// spurious diff from the server filling in defaults.
+	if usingTyped {
+		return flattenDetector(d, res.Detector)
+	}
+
+	return nil
 }
 
 func resourceElasticsearchOpenDistroDetectorUpdate(d *schema.ResourceData, m interface{}) error {
@@ -97,8 +238,6 @@ func resourceElasticsearchOpenDistroDetectorUpdate(d *schema.ResourceData, m int
 }
 
 func resourceElasticsearchOpenDistroDetectorDelete(d *schema.ResourceData, m interface{}) error {
-	var err error
-
 	path, err := uritemplates.Expand("/_opendistro/_anomaly_detection/detectors/{id}", map[string]string{
 		"id": d.Id(),
 	})
@@ -110,26 +249,16 @@ func resourceElasticsearchOpenDistroDetectorDelete(d *schema.ResourceData, m int
 	if err != nil {
 		return err
 	}
-	switch client := esClient.(type) {
-	case *elastic7.Client:
-		_, err = client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
-			Method: "DELETE",
-			Path:   path,
-		})
-	case *elastic6.Client:
-		_, err = client.PerformRequest(context.TODO(), elastic6.PerformRequestOptions{
-			Method: "DELETE",
-			Path:   path,
-		})
-	default:
-		err = errors.New("Detector resource not implemented prior to Elastic v6")
-	}
+
+	_, err = performRequestWithBackoff(context.Background(), m, esClient, backoffRequestOptions{
+		Method: "DELETE",
+		Path:   path,
+	})
 
 	return err
 }
 
 func resourceElasticsearchOpenDistroGetDetector(DetectorID string, m interface{}) (*DetectorResponse, error) {
-	var err error
 	response := new(DetectorResponse)
 
 	path, err := uritemplates.Expand("/_opendistro/_anomaly_detection/detectors/{id}", map[string]string{
@@ -139,30 +268,15 @@ func resourceElasticsearchOpenDistroGetDetector(DetectorID string, m interface{}
 		return response, fmt.Errorf("error building URL path for Detector: %+v", err)
 	}
 
-	var body json.RawMessage
 	esClient, err := getClient(m.(*ProviderConf))
 	if err != nil {
 		return nil, err
 	}
-	switch client := esClient.(type) {
-	case *elastic7.Client:
-		var res *elastic7.Response
-		res, err = client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
-			Method: "GET",
-			Path:   path,
-		})
-		body = res.Body
-	case *elastic6.Client:
-		var res *elastic6.Response
-		res, err = client.PerformRequest(context.TODO(), elastic6.PerformRequestOptions{
-			Method: "GET",
-			Path:   path,
-		})
-		body = res.Body
-	default:
-		err = errors.New("Detector resource not implemented prior to Elastic v6")
-	}
 
+	body, err := performRequestWithBackoff(context.Background(), m, esClient, backoffRequestOptions{
+		Method: "GET",
+		Path:   path,
+	})
 	if err != nil {
 		return response, err
 	}
@@ -175,39 +289,25 @@ func resourceElasticsearchOpenDistroGetDetector(DetectorID string, m interface{}
 }
 
 func resourceElasticsearchOpenDistroPostDetector(d *schema.ResourceData, m interface{}) (*DetectorResponse, error) {
-	DetectorJSON := d.Get("body").(string)
+	DetectorJSON, err := buildDetectorBody(d)
+	if err != nil {
+		return nil, err
+	}
 
-	var err error
 	response := new(DetectorResponse)
 
 	path := "/_opendistro/_anomaly_detection/detectors/"
 
-	var body json.RawMessage
 	esClient, err := getClient(m.(*ProviderConf))
 	if err != nil {
 		return nil, err
 	}
-	switch client := esClient.(type) {
-	case *elastic7.Client:
-		var res *elastic7.Response
-		res, err = client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
-			Method: "POST",
-			Path:   path,
-			Body:   DetectorJSON,
-		})
-		body = res.Body
-	case *elastic6.Client:
-		var res *elastic6.Response
-		res, err = client.PerformRequest(context.TODO(), elastic6.PerformRequestOptions{
-			Method: "POST",
-			Path:   path,
-			Body:   DetectorJSON,
-		})
-		body = res.Body
-	default:
-		err = errors.New("Detector resource not implemented prior to Elastic v6")
-	}
 
+	body, err := performRequestWithBackoff(context.Background(), m, esClient, backoffRequestOptions{
+		Method: "POST",
+		Path:   path,
+		Body:   DetectorJSON,
+	})
 	if err != nil {
 		return response, err
 	}
@@ -220,9 +320,11 @@ func resourceElasticsearchOpenDistroPostDetector(d *schema.ResourceData, m inter
 }
 
 func resourceElasticsearchOpenDistroPutDetector(d *schema.ResourceData, m interface{}) (*DetectorResponse, error) {
-	DetectorJSON := d.Get("body").(string)
+	DetectorJSON, err := buildDetectorBody(d)
+	if err != nil {
+		return nil, err
+	}
 
-	var err error
 	response := new(DetectorResponse)
 
 	path, err := uritemplates.Expand("/_opendistro/_anomaly_detection/detectors/{id}", map[string]string{
@@ -232,32 +334,16 @@ func resourceElasticsearchOpenDistroPutDetector(d *schema.ResourceData, m interf
 		return response, fmt.Errorf("error building URL path for Detector: %+v", err)
 	}
 
-	var body json.RawMessage
 	esClient, err := getClient(m.(*ProviderConf))
 	if err != nil {
 		return nil, err
 	}
-	switch client := esClient.(type) {
-	case *elastic7.Client:
-		var res *elastic7.Response
-		res, err = client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
-			Method: "PUT",
-			Path:   path,
-			Body:   DetectorJSON,
-		})
-		body = res.Body
-	case *elastic6.Client:
-		var res *elastic6.Response
-		res, err = client.PerformRequest(context.TODO(), elastic6.PerformRequestOptions{
-			Method: "PUT",
-			Path:   path,
-			Body:   DetectorJSON,
-		})
-		body = res.Body
-	default:
-		err = errors.New("Detector resource not implemented prior to Elastic v6")
-	}
 
+	body, err := performRequestWithBackoff(context.Background(), m, esClient, backoffRequestOptions{
+		Method: "PUT",
+		Path:   path,
+		Body:   DetectorJSON,
+	})
 	if err != nil {
 		return response, err
 	}
@@ -269,8 +355,235 @@ func resourceElasticsearchOpenDistroPutDetector(d *schema.ResourceData, m interf
 	return response, nil
 }
 
+// usingTypedDetectorAttributes reports whether the resource was configured
+// with any of the typed attributes, as opposed to a hand-written `body`.
+// `body` is Optional+Computed, so once a detector has been created its
+// runtime value is always populated, even for typed-attribute configs;
+// keying off the typed attributes instead (none of which are Computed) is
+// the only reliable way to tell which path the user actually configured.
+func usingTypedDetectorAttributes(d *schema.ResourceData) bool {
+	for _, field := range typedDetectorFields {
+		if _, ok := d.GetOk(field); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// resourceElasticsearchOpenDistroDetectorCustomizeDiff rejects a config that
+// configures neither `body` nor any typed attribute. `body` is
+// Optional+Computed and every typed attribute is Optional, so without this
+// check an empty detector block passes schema validation and buildDetectorBody
+// would silently POST a blank detector instead of failing in config.
+//
+// Either one being set is enough, so a config migrating from typed
+// attributes to `body` in a single change - the direction ConflictsWith
+// already allows alongside body-to-typed - is accepted: once `body` is newly
+// configured, the typed attributes being removed in the same diff isn't an
+// error.
+func resourceElasticsearchOpenDistroDetectorCustomizeDiff(diff *schema.ResourceDiff, m interface{}) error {
+	if _, ok := diff.GetOk("body"); ok {
+		return nil
+	}
+	for _, field := range typedDetectorFields {
+		if _, ok := diff.GetOk(field); ok {
+			return nil
+		}
+	}
+	return fmt.Errorf("one of `body` or the typed detector attributes (%s) must be configured", strings.Join(typedDetectorFields, ", "))
+}
+
+// buildDetectorBody returns the JSON payload to send to the AD plugin for a
+// create/update call. When the typed attributes aren't in use, `body` is
+// passed through verbatim for backward compatibility; otherwise the payload
+// is assembled from the typed attributes.
+func buildDetectorBody(d *schema.ResourceData) (string, error) {
+	if !usingTypedDetectorAttributes(d) {
+		if v, ok := d.GetOk("body"); ok {
+			return v.(string), nil
+		}
+	}
+
+	detector := map[string]interface{}{
+		"name":        d.Get("name").(string),
+		"description": d.Get("description").(string),
+		"time_field":  d.Get("time_field").(string),
+		"indices":     d.Get("indices").([]interface{}),
+	}
+
+	if v, ok := d.GetOk("shingle_size"); ok {
+		detector["shingle_size"] = v.(int)
+	}
+
+	if v, ok := d.GetOk("category_field"); ok {
+		detector["category_field"] = v.([]interface{})
+	}
+
+	if v, ok := d.GetOk("filter_query"); ok {
+		filterQuery, err := structure.ExpandJsonFromString(v.(string))
+		if err != nil {
+			return "", fmt.Errorf("error unmarshalling filter_query: %+v", err)
+		}
+		detector["filter_query"] = filterQuery
+	}
+
+	if v, ok := d.GetOk("detection_interval"); ok {
+		period, err := expandDetectorIntervalBlock(v.([]interface{}))
+		if err != nil {
+			return "", err
+		}
+		if period != nil {
+			detector["detection_interval"] = map[string]interface{}{"period": period}
+		}
+	}
+
+	if v, ok := d.GetOk("window_delay"); ok {
+		period, err := expandDetectorIntervalBlock(v.([]interface{}))
+		if err != nil {
+			return "", err
+		}
+		if period != nil {
+			detector["window_delay"] = map[string]interface{}{"period": period}
+		}
+	}
+
+	if v, ok := d.GetOk("feature_attributes"); ok {
+		features, err := expandDetectorFeatureAttributes(v.([]interface{}))
+		if err != nil {
+			return "", err
+		}
+		detector["feature_attributes"] = features
+	}
+
+	detectorJSON, err := json.Marshal(detector)
+	if err != nil {
+		return "", fmt.Errorf("error marshalling typed Detector attributes: %+v", err)
+	}
+
+	return string(detectorJSON), nil
+}
+
+func expandDetectorIntervalBlock(raw []interface{}) (map[string]interface{}, error) {
+	if len(raw) == 0 || raw[0] == nil {
+		return nil, nil
+	}
+	block := raw[0].(map[string]interface{})
+	return map[string]interface{}{
+		"interval": block["interval"],
+		"unit":     block["unit"],
+	}, nil
+}
+
+func expandDetectorFeatureAttributes(raw []interface{}) ([]map[string]interface{}, error) {
+	features := make([]map[string]interface{}, 0, len(raw))
+	for _, r := range raw {
+		f := r.(map[string]interface{})
+
+		aggregationQuery, err := structure.ExpandJsonFromString(f["aggregation_query"].(string))
+		if err != nil {
+			return nil, fmt.Errorf("error unmarshalling aggregation_query: %+v", err)
+		}
+
+		features = append(features, map[string]interface{}{
+			"feature_name":      f["feature_name"].(string),
+			"feature_enabled":   f["feature_enabled"].(bool),
+			"aggregation_query": aggregationQuery,
+		})
+	}
+	return features, nil
+}
+
+// flattenDetector sets the typed detector attributes in state from the raw
+// detector object returned by the AD plugin. It's a best-effort mirror of
+// `body` for configurations that used the typed attributes; fields the API
+// didn't return are left unset.
+func flattenDetector(d *schema.ResourceData, detector map[string]interface{}) error {
+	if v, ok := detector["name"]; ok {
+		if err := d.Set("name", v); err != nil {
+			return err
+		}
+	}
+	if v, ok := detector["description"]; ok {
+		if err := d.Set("description", v); err != nil {
+			return err
+		}
+	}
+	if v, ok := detector["time_field"]; ok {
+		if err := d.Set("time_field", v); err != nil {
+			return err
+		}
+	}
+	if v, ok := detector["indices"]; ok {
+		if err := d.Set("indices", v); err != nil {
+			return err
+		}
+	}
+	if v, ok := detector["category_field"]; ok {
+		if err := d.Set("category_field", v); err != nil {
+			return err
+		}
+	}
+	if v, ok := detector["shingle_size"]; ok {
+		if err := d.Set("shingle_size", v); err != nil {
+			return err
+		}
+	}
+
+	if v, ok := detector["filter_query"]; ok {
+		filterQueryJSON, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		if err := d.Set("filter_query", string(filterQueryJSON)); err != nil {
+			return err
+		}
+	}
+
+	for _, key := range []string{"detection_interval", "window_delay"} {
+		raw, ok := detector[key]
+		if !ok {
+			continue
+		}
+		block, ok := raw.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		period, ok := block["period"].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if err := d.Set(key, []interface{}{period}); err != nil {
+			return err
+		}
+	}
+
+	if raw, ok := detector["feature_attributes"].([]interface{}); ok {
+		features := make([]interface{}, 0, len(raw))
+		for _, r := range raw {
+			f, ok := r.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			aggregationQueryJSON, err := json.Marshal(f["aggregation_query"])
+			if err != nil {
+				return err
+			}
+			features = append(features, map[string]interface{}{
+				"feature_name":      f["feature_name"],
+				"feature_enabled":   f["feature_enabled"],
+				"aggregation_query": string(aggregationQueryJSON),
+			})
+		}
+		if err := d.Set("feature_attributes", features); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 type DetectorResponse struct {
-	Version int                    `json:"_version"`
-	ID      string                 `json:"_id"`
+	Version  int                    `json:"_version"`
+	ID       string                 `json:"_id"`
 	Detector map[string]interface{} `json:"Detector"`
 }
@@ -0,0 +1,79 @@
+package es
+
+import (
+	"fmt"
+	"testing"
+
+	elastic5 "gopkg.in/olivere/elastic.v5"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+)
+
+func TestAccElasticsearchOpenDistroDetectorJob(t *testing.T) {
+	provider := Provider().(*schema.Provider)
+	err := provider.Configure(&terraform.ResourceConfig{})
+	if err != nil {
+		t.Skipf("err: %s", err)
+	}
+	meta := provider.Meta()
+	esClient, err := getClient(meta.(*ProviderConf))
+	if err != nil {
+		t.Skipf("err: %s", err)
+	}
+	var allowed bool
+
+	switch esClient.(type) {
+	case *elastic5.Client:
+		allowed = false
+	default:
+		allowed = true
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			if !allowed {
+				t.Skip("Detector jobs only supported on >= ES 6")
+			}
+		},
+		Providers: testAccOpendistroProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccElasticsearchOpenDistroDetectorJob,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckElasticsearchOpenDistroDetectorJobExists("elasticsearch_opendistro_detector_job.test_job"),
+				),
+			},
+		},
+	})
+}
+
+func testCheckElasticsearchOpenDistroDetectorJobExists(name string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[name]
+		if !ok {
+			return fmt.Errorf("Not found: %s", name)
+		}
+		if rs.Primary.ID == "" {
+			return fmt.Errorf("No Detector job ID is set")
+		}
+
+		meta := testAccOpendistroProvider.Meta()
+
+		_, err := resourceElasticsearchOpenDistroGetDetectorProfile(rs.Primary.ID, meta.(*ProviderConf))
+		if err != nil {
+			return err
+		}
+
+		return nil
+	}
+}
+
+var testAccElasticsearchOpenDistroDetectorJob = testAccElasticsearchOpenDistroDetectorFixture + `
+resource elasticsearch_opendistro_detector_job test_job {
+  detector_id = elasticsearch_opendistro_detector.detector.id
+  enabled     = true
+}
+`
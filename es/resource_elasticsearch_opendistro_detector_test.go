@@ -2,6 +2,7 @@ package es
 
 import (
 	"fmt"
+	"regexp"
 	"testing"
 
 	elastic7 "github.com/olivere/elastic/v7"
@@ -44,7 +45,7 @@ func TestAccElasticsearchOpenDistroDetector(t *testing.T) {
 		CheckDestroy: testCheckElasticsearchDetectorDestroy,
 		Steps: []resource.TestStep{
 			{
-				Config: testAccElasticsearchOpenDistroDetector,
+				Config: testAccElasticsearchOpenDistroDetectorFixture,
 				Check: resource.ComposeTestCheckFunc(
 					testCheckElasticsearchOpenDistroDetectorExists("elasticsearch_opendistro_detector.test_detector"),
 				),
@@ -53,6 +54,26 @@ func TestAccElasticsearchOpenDistroDetector(t *testing.T) {
 	})
 }
 
+func TestAccElasticsearchOpenDistroDetector_requiresBodyOrTypedAttributes(t *testing.T) {
+	provider := Provider().(*schema.Provider)
+	err := provider.Configure(&terraform.ResourceConfig{})
+	if err != nil {
+		t.Skipf("err: %s", err)
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccOpendistroProviders,
+		CheckDestroy: testCheckElasticsearchDetectorDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config:      `resource elasticsearch_opendistro_detector detector {}`,
+				ExpectError: regexp.MustCompile("one of `body` or the typed detector attributes"),
+			},
+		},
+	})
+}
+
 func testCheckElasticsearchOpenDistroDetectorExists(name string) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
 		rs, ok := s.RootModule().Resources[name]
@@ -118,10 +139,13 @@ func testCheckElasticsearchDetectorDestroy(s *terraform.State) error {
 	return nil
 }
 
-var testAccElasticsearchOpenDistroDetector = `
+// testAccElasticsearchOpenDistroDetectorFixture is the `body`-based detector
+// config shared by the acceptance tests in this series (detector job,
+// anomaly monitor, detector results data source) so that the `body` <->
+// typed-attribute `ConflictsWith` wiring doesn't need to be worked around in
+// each one separately.
+var testAccElasticsearchOpenDistroDetectorFixture = `
 resource elasticsearch_opendistro_detector detector {
-  name = "detector"
-  description = "something"
   body = <<EOT
 {
 	"name": "detector",